@@ -0,0 +1,322 @@
+package firebaseauth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"crypto/rsa"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+/**
+Google Firebase APP CHECK - echo middleware definition
+*/
+
+const (
+	// DefaultAppCheckHeader is the header Firebase App Check tokens are read from.
+	DefaultAppCheckHeader = "X-Firebase-AppCheck"
+
+	appCheckJWKSURL    = "https://firebaseappcheck.googleapis.com/v1/jwks"
+	appCheckIssuerBase = "https://firebaseappcheck.googleapis.com/"
+)
+
+type (
+	// AppCheckConfig defines the config for the Firebase App Check middleware.
+	AppCheckConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper middleware.Skipper
+
+		// Header is the request header App Check tokens are read from.
+		// Optional. Default value "X-Firebase-AppCheck".
+		Header string
+
+		// ContextAppIDKey is the context key the verified app ID is stored under.
+		// Optional. Default value "app-id".
+		ContextAppIDKey string
+
+		// ProjectNumber is the numeric Firebase project number. It is used to
+		// validate the token's issuer and audience.
+		ProjectNumber string
+
+		// ProjectID is the Firebase project ID, accepted as an additional
+		// audience alongside ProjectNumber.
+		ProjectID string
+
+		// HTTPClient is used to fetch the App Check JWK set.
+		// Optional. Default value http.DefaultClient.
+		HTTPClient *http.Client
+	}
+
+	// jwk is a single JSON Web Key as returned by the App Check JWKS endpoint.
+	jwk struct {
+		Kid string `json:"kid"`
+		Kty string `json:"kty"`
+		Alg string `json:"alg"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	}
+
+	jwkSet struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	// appCheckJWKCache fetches and caches the App Check JWK set, honoring the
+	// HTTP Cache-Control max-age sent by the JWKS endpoint.
+	appCheckJWKCache struct {
+		httpClient *http.Client
+
+		mu        sync.Mutex
+		keys      map[string]*rsa.PublicKey
+		expiresAt time.Time
+	}
+)
+
+// ErrAppCheckTokenMissing is returned when the App Check header is absent.
+var ErrAppCheckTokenMissing = echo.NewHTTPError(http.StatusUnauthorized, "Missing Firebase App Check token")
+
+// ErrAppCheckTokenInvalid is returned when the App Check token fails verification.
+var ErrAppCheckTokenInvalid = echo.NewHTTPError(http.StatusUnauthorized, "Invalid or expired Firebase App Check token")
+
+// DefaultAppCheckConfig is the default App Check middleware config.
+var DefaultAppCheckConfig = AppCheckConfig{
+	Skipper:         middleware.DefaultSkipper,
+	Header:          DefaultAppCheckHeader,
+	ContextAppIDKey: "app-id",
+}
+
+// AppCheck returns a middleware that verifies Firebase App Check tokens.
+//
+// For a valid token, it stores the attested app ID in context and calls the
+// next handler. For a missing or invalid token, it returns a
+// "401 - Unauthorized" error.
+//
+// Deprecated: AppCheck calls NewAppCheck and log.Fatal's on any
+// configuration error. Use NewAppCheck instead to receive the error and
+// handle it yourself.
+func AppCheck(projectNumber, projectID string) echo.MiddlewareFunc {
+	c := DefaultAppCheckConfig
+	c.ProjectNumber = projectNumber
+	c.ProjectID = projectID
+	return WithAppCheckConfig(c)
+}
+
+// WithAppCheckConfig returns an App Check middleware with config.
+// See: `AppCheck()`.
+//
+// Deprecated: WithAppCheckConfig calls NewAppCheck and log.Fatal's on any
+// configuration error, which makes it unusable in test harnesses. Use
+// NewAppCheck instead to receive the error and handle it yourself.
+func WithAppCheckConfig(config AppCheckConfig) echo.MiddlewareFunc {
+	mw, err := NewAppCheck(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return mw
+}
+
+// NewAppCheck returns an App Check middleware with config, or an error if
+// the config is invalid. See: `WithAppCheckConfig()`.
+func NewAppCheck(config AppCheckConfig) (echo.MiddlewareFunc, error) {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultAppCheckConfig.Skipper
+	}
+	if config.Header == "" {
+		config.Header = DefaultAppCheckConfig.Header
+	}
+	if config.ContextAppIDKey == "" {
+		config.ContextAppIDKey = DefaultAppCheckConfig.ContextAppIDKey
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = http.DefaultClient
+	}
+	if config.ProjectNumber == "" {
+		return nil, errors.New("firebaseauth: AppCheck middleware requires ProjectNumber")
+	}
+
+	jwks := newAppCheckJWKCache(config.HTTPClient)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			raw := c.Request().Header.Get(config.Header)
+			if raw == "" {
+				return ErrAppCheckTokenMissing
+			}
+
+			appID, err := verifyAppCheckToken(raw, jwks, config.ProjectNumber, config.ProjectID)
+			if err != nil {
+				return appCheckUnauthorized(err)
+			}
+
+			c.Set(config.ContextAppIDKey, appID)
+			return next(c)
+		}
+	}, nil
+}
+
+// verifyAppCheckToken validates the RS256 signature, issuer, audience and
+// standard time claims of an App Check token, returning the attested app ID.
+func verifyAppCheckToken(raw string, jwks *appCheckJWKCache, projectNumber, projectID string) (string, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (interface{}, error) {
+		if t.Method.Alg() != "RS256" {
+			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+		}
+		kid, _ := t.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.New("token is missing kid header")
+		}
+		return jwks.key(kid)
+	}, jwt.WithValidMethods([]string{"RS256"}), jwt.WithIssuedAt())
+	if err != nil {
+		return "", err
+	}
+
+	wantIssuer := appCheckIssuerBase + projectNumber
+	if iss, _ := claims.GetIssuer(); iss != wantIssuer {
+		return "", fmt.Errorf("unexpected issuer: %q", iss)
+	}
+
+	wantAudiences := []string{"projects/" + projectNumber}
+	if projectID != "" {
+		wantAudiences = append(wantAudiences, "projects/"+projectID)
+	}
+	aud, _ := claims.GetAudience()
+	if !containsAny(aud, wantAudiences...) {
+		return "", fmt.Errorf("unexpected audience: %v", aud)
+	}
+
+	sub, _ := claims.GetSubject()
+	if sub == "" {
+		return "", errors.New("token is missing sub claim")
+	}
+
+	return sub, nil
+}
+
+func containsAny(haystack []string, needles ...string) bool {
+	for _, h := range haystack {
+		for _, n := range needles {
+			if n != "" && h == n {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func appCheckUnauthorized(err error) *echo.HTTPError {
+	return &echo.HTTPError{
+		Code:     ErrAppCheckTokenInvalid.Code,
+		Message:  ErrAppCheckTokenInvalid.Message,
+		Internal: err,
+	}
+}
+
+func newAppCheckJWKCache(httpClient *http.Client) *appCheckJWKCache {
+	return &appCheckJWKCache{httpClient: httpClient}
+}
+
+// key returns the RSA public key for kid, refreshing the cached JWK set from
+// the App Check JWKS endpoint if it is missing or stale.
+func (c *appCheckJWKCache) key(kid string) (*rsa.PublicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if key, ok := c.keys[kid]; ok && time.Now().Before(c.expiresAt) {
+		return key, nil
+	}
+
+	keys, maxAge, err := fetchAppCheckJWKS(c.httpClient)
+	if err != nil {
+		return nil, err
+	}
+	c.keys = keys
+	c.expiresAt = time.Now().Add(maxAge)
+
+	key, ok := c.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("no matching key found for kid %q", kid)
+	}
+	return key, nil
+}
+
+func fetchAppCheckJWKS(httpClient *http.Client) (map[string]*rsa.PublicKey, time.Duration, error) {
+	resp, err := httpClient.Get(appCheckJWKSURL)
+	if err != nil {
+		return nil, 0, fmt.Errorf("fetching App Check JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("fetching App Check JWKS: unexpected status %d", resp.StatusCode)
+	}
+
+	var set jwkSet
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, 0, fmt.Errorf("decoding App Check JWKS: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		pubKey, err := k.rsaPublicKey()
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = pubKey
+	}
+
+	return keys, maxAgeFromCacheControl(resp.Header.Get("Cache-Control")), nil
+}
+
+func (k jwk) rsaPublicKey() (*rsa.PublicKey, error) {
+	nBytes, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("decoding modulus: %w", err)
+	}
+	eBytes, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("decoding exponent: %w", err)
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: int(new(big.Int).SetBytes(eBytes).Int64()),
+	}, nil
+}
+
+// maxAgeFromCacheControl returns the max-age directive of a Cache-Control
+// header, falling back to six hours (the App Check JWKS default) if absent
+// or malformed.
+func maxAgeFromCacheControl(cacheControl string) time.Duration {
+	const defaultMaxAge = 6 * time.Hour
+
+	for _, directive := range strings.Split(cacheControl, ",") {
+		directive = strings.TrimSpace(directive)
+		if !strings.HasPrefix(directive, "max-age=") {
+			continue
+		}
+		seconds, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age="))
+		if err != nil || seconds <= 0 {
+			continue
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	return defaultMaxAge
+}
@@ -0,0 +1,109 @@
+package firebaseauth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestVerifyAppCheckToken(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating test key: %v", err)
+	}
+	jwks := &appCheckJWKCache{
+		keys:      map[string]*rsa.PublicKey{testKid: &priv.PublicKey},
+		expiresAt: time.Now().Add(time.Hour),
+	}
+
+	const (
+		projectNumber = "123456789"
+		projectID     = "my-project"
+	)
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss": appCheckIssuerBase + projectNumber,
+			"aud": []string{"projects/" + projectNumber, "projects/" + projectID},
+			"sub": "app-id-123",
+			"iat": jwt.NewNumericDate(time.Now().Add(-time.Minute)),
+		}
+	}
+
+	t.Run("valid token", func(t *testing.T) {
+		raw := signTestToken(t, priv, validClaims())
+		appID, err := verifyAppCheckToken(raw, jwks, projectNumber, projectID)
+		if err != nil {
+			t.Fatalf("verifyAppCheckToken() error = %v, want nil", err)
+		}
+		if appID != "app-id-123" {
+			t.Errorf("appID = %q, want %q", appID, "app-id-123")
+		}
+	})
+
+	t.Run("wrong issuer", func(t *testing.T) {
+		claims := validClaims()
+		claims["iss"] = appCheckIssuerBase + "other-project-number"
+		raw := signTestToken(t, priv, claims)
+		if _, err := verifyAppCheckToken(raw, jwks, projectNumber, projectID); err == nil {
+			t.Error("verifyAppCheckToken() error = nil, want an issuer mismatch error")
+		}
+	})
+
+	t.Run("wrong audience", func(t *testing.T) {
+		claims := validClaims()
+		claims["aud"] = []string{"projects/other-project-number"}
+		raw := signTestToken(t, priv, claims)
+		if _, err := verifyAppCheckToken(raw, jwks, projectNumber, projectID); err == nil {
+			t.Error("verifyAppCheckToken() error = nil, want an audience mismatch error")
+		}
+	})
+
+	t.Run("missing subject", func(t *testing.T) {
+		claims := validClaims()
+		delete(claims, "sub")
+		raw := signTestToken(t, priv, claims)
+		if _, err := verifyAppCheckToken(raw, jwks, projectNumber, projectID); err == nil {
+			t.Error("verifyAppCheckToken() error = nil, want a missing sub error")
+		}
+	})
+
+	t.Run("issued in the future", func(t *testing.T) {
+		claims := validClaims()
+		claims["iat"] = jwt.NewNumericDate(time.Now().Add(time.Hour))
+		raw := signTestToken(t, priv, claims)
+		if _, err := verifyAppCheckToken(raw, jwks, projectNumber, projectID); err == nil {
+			t.Error("verifyAppCheckToken() error = nil, want an iat-in-the-future error")
+		}
+	})
+
+	t.Run("audience matches without ProjectID configured", func(t *testing.T) {
+		claims := validClaims()
+		claims["aud"] = []string{"projects/" + projectNumber}
+		raw := signTestToken(t, priv, claims)
+		if _, err := verifyAppCheckToken(raw, jwks, projectNumber, ""); err != nil {
+			t.Errorf("verifyAppCheckToken() error = %v, want nil", err)
+		}
+	})
+
+	t.Run("bare 'projects/' audience rejected without ProjectID configured", func(t *testing.T) {
+		claims := validClaims()
+		claims["aud"] = []string{"projects/"}
+		raw := signTestToken(t, priv, claims)
+		if _, err := verifyAppCheckToken(raw, jwks, projectNumber, ""); err == nil {
+			t.Error("verifyAppCheckToken() error = nil, want an audience mismatch error")
+		}
+	})
+}
+
+func TestNewAppCheckRequiresProjectNumber(t *testing.T) {
+	mw, err := NewAppCheck(AppCheckConfig{})
+	if err == nil {
+		t.Fatalf("NewAppCheck() = (%v, nil), want a non-nil error for a missing ProjectNumber", mw)
+	}
+	if mw != nil {
+		t.Error("NewAppCheck() returned a non-nil middleware alongside the error")
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"errors"
 	firebase "firebase.google.com/go/v4"
+	fbauth "firebase.google.com/go/v4/auth"
 	"fmt"
 	"google.golang.org/api/option"
+	"log"
 	"net/http"
 	"strings"
 
@@ -58,6 +60,59 @@ type (
 		AuthScheme string
 
 		CredentialJSON []byte
+
+		// CredentialsFile is a path to a service account JSON file, used as
+		// an alternative to CredentialJSON.
+		CredentialsFile string
+
+		// UseADC makes the middleware initialize the Firebase app with
+		// Application Default Credentials. It takes effect only when
+		// neither CredentialJSON nor CredentialsFile is set; those take
+		// precedence.
+		UseADC bool
+
+		// ProjectID is the Firebase project ID, required when KeySource is
+		// set so the local verifier can check the token's issuer and
+		// audience.
+		ProjectID string
+
+		// KeySource, when set, makes the middleware verify ID tokens
+		// locally against its cached public keys instead of calling
+		// `client.VerifyIDToken` over the network on every request.
+		// Not used when TenantResolver is set: tenant ID tokens are then
+		// verified by the tenant's Auth client.
+		// Optional. See `NewHTTPKeySource`.
+		KeySource KeySource
+
+		// AppCheck, when set, requires a valid Firebase App Check token on
+		// the AppCheck.Header header (default X-Firebase-AppCheck) before
+		// the Authorization check below runs. See `AppCheckConfig`.
+		AppCheck *AppCheckConfig
+
+		// RoleCache, when set, caches GetRoles results by email for
+		// RoleCache.TTL so GetRoles isn't invoked on every request.
+		// Optional. See `NewRoleCache`.
+		RoleCache *RoleCache
+
+		// TenantResolver, when set, resolves the Firebase Auth tenant each
+		// request belongs to, and the middleware verifies the ID token
+		// against that tenant's Auth client instead of the default-project
+		// client. Requires CredentialJSON, CredentialsFile or UseADC.
+		TenantResolver TenantResolver
+
+		// AllowedTenants allowlists the tenant IDs TenantResolver may
+		// resolve to. Requests resolving to any other tenant are rejected
+		// with 401 before Firebase is contacted. Optional; an empty list
+		// allows every tenant.
+		AllowedTenants []string
+
+		// SessionCookieMode makes the middleware verify the extracted
+		// token as a Firebase session cookie via
+		// VerifySessionCookieAndCheckRevoked instead of VerifyIDToken.
+		// Requires CredentialJSON, CredentialsFile or UseADC; cannot be
+		// combined with KeySource or TenantResolver. See
+		// `NewSessionLoginHandler`.
+		SessionCookieMode bool
 	}
 
 	tokenExtractorFunc func(echo.Context) (string, error)
@@ -80,9 +135,6 @@ var (
 	}
 )
 
-// GetRolesFunc is an external closure function that can retrieve roles by email.
-type GetRolesFunc func(email string) []string
-
 // FirebaseAuth returns a JSON Web Token (JWT) auth middleware.
 //
 // For valid token, it sets the user in context and calls next handler.
@@ -95,7 +147,23 @@ func FirebaseAuth() echo.MiddlewareFunc {
 
 // WithConfig returns a FirebaseAuth middleware with config.
 // See: `FirebaseAuth()`.
+//
+// Deprecated: WithConfig calls New and log.Fatal's on any configuration
+// error, which makes it unusable in test harnesses and dynamic
+// multi-tenant setups. Use New instead to receive the error and handle it
+// yourself.
 func WithConfig(config Config) echo.MiddlewareFunc {
+	mw, err := New(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return mw
+}
+
+// New returns a FirebaseAuth middleware with config, or an error if the
+// config is invalid or the underlying Firebase app/Auth client cannot be
+// initialized. See: `WithConfig()`.
+func New(config Config) (echo.MiddlewareFunc, error) {
 	// Defaults
 	if config.Skipper == nil {
 		config.Skipper = DefaultFirebaseAuthConfig.Skipper
@@ -126,52 +194,110 @@ func WithConfig(config Config) echo.MiddlewareFunc {
 		extractor = tokenFromCookie(parts[1])
 	}
 
-	if len(config.CredentialJSON) == 0 {
-		panic("echo: FirebaseAuth middleware requires CredentialJSON")
+	if config.KeySource != nil && config.ProjectID == "" {
+		return nil, errors.New("firebaseauth: ProjectID is required when KeySource is set")
 	}
-	authApp, err := firebase.NewApp(
-		context.Background(),
-		nil,
-		option.WithCredentialsJSON(config.CredentialJSON))
-	if err != nil {
-		panic(fmt.Errorf("error initializing app: %v", err))
+
+	hasCredentials := len(config.CredentialJSON) > 0 || config.CredentialsFile != "" || config.UseADC
+
+	if config.TenantResolver != nil && !hasCredentials {
+		return nil, errors.New("firebaseauth: TenantResolver requires one of CredentialJSON, CredentialsFile or UseADC")
 	}
-	// Access auth service from the default app
-	client, err := authApp.Auth(context.Background())
-	if err != nil {
-		panic(fmt.Errorf("error getting Auth client: %v", err))
+
+	if config.SessionCookieMode {
+		if config.KeySource != nil {
+			return nil, errors.New("firebaseauth: SessionCookieMode cannot be combined with KeySource")
+		}
+		if config.TenantResolver != nil {
+			return nil, errors.New("firebaseauth: SessionCookieMode cannot be combined with TenantResolver")
+		}
+		if !hasCredentials {
+			return nil, errors.New("firebaseauth: SessionCookieMode requires one of CredentialJSON, CredentialsFile or UseADC")
+		}
 	}
 
-	return func(next echo.HandlerFunc) echo.HandlerFunc {
+	// The Auth client is only required to call VerifyIDToken over the
+	// network (when no KeySource is configured), to resolve per-tenant
+	// clients, or to serve X-GetUser. A KeySource lets callers skip
+	// shipping a full service account JSON.
+	var client *fbauth.Client
+	var tenants *tenantClientCache
+	if config.KeySource == nil || hasCredentials {
+		if !hasCredentials {
+			return nil, errors.New("firebaseauth: one of CredentialJSON, CredentialsFile or UseADC is required")
+		}
+		var opts []option.ClientOption
+		switch {
+		case len(config.CredentialJSON) > 0:
+			opts = append(opts, option.WithCredentialsJSON(config.CredentialJSON))
+		case config.CredentialsFile != "":
+			opts = append(opts, option.WithCredentialsFile(config.CredentialsFile))
+		}
+		authApp, err := firebase.NewApp(context.Background(), nil, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("firebaseauth: initializing app: %w", err)
+		}
+		// Access auth service from the default app
+		client, err = authApp.Auth(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("firebaseauth: getting Auth client: %w", err)
+		}
+		if config.TenantResolver != nil {
+			tenants = newTenantClientCache(client.TenantManager)
+		}
+	}
+
+	authMiddleware := func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			if config.Skipper(c) {
 				return next(c)
 			}
 
-			auth, err := extractor(c)
+			rawToken, err := extractor(c)
 			if err != nil {
 				return err
 			}
 
-			_, _ = client.GetUser(context.Background(), auth)
-			tok, err := client.VerifyIDToken(context.Background(), auth)
+			var tok *fbauth.Token
+			switch {
+			case config.TenantResolver != nil:
+				tenantID, terr := config.TenantResolver(c)
+				if terr != nil {
+					return unauthorized(terr)
+				}
+				if !tenantAllowed(config.AllowedTenants, tenantID) {
+					return unauthorized(fmt.Errorf("tenant %q is not allowed", tenantID))
+				}
+				tenantClient, terr := tenants.client(tenantID)
+				if terr != nil {
+					return unauthorized(terr)
+				}
+				tok, err = tenantClient.VerifyIDToken(context.Background(), rawToken)
+			case config.SessionCookieMode:
+				tok, err = client.VerifySessionCookieAndCheckRevoked(context.Background(), rawToken)
+			case config.KeySource != nil:
+				tok, err = verifyIDTokenLocal(rawToken, config.KeySource, config.ProjectID)
+			default:
+				tok, err = client.VerifyIDToken(context.Background(), rawToken)
+			}
 			if err != nil {
 				return unauthorized(err)
 			}
 			// Store user information from token into context.
 			jsTok, _ := json.Marshal(tok)
 			// Store userID into context.
-			emailInterface := tok.Firebase.Identities["email"].([]interface{})
-			if emailInterface != nil {
-				// emailList := make([]string, len(emailInterface))
-				if len(emailInterface) > 0 {
-					c.Set(config.ContextUserIDKey, emailInterface[0].(string))
-				}
+			var email string
+			if emailInterface, _ := tok.Firebase.Identities["email"].([]interface{}); len(emailInterface) > 0 {
+				email, _ = emailInterface[0].(string)
+				c.Set(config.ContextUserIDKey, email)
 			}
 			c.Set(config.ContextIDKey, string(jsTok))
 			c.Set("auth-provider", "firebase")
 			if config.GetRoles != nil {
-				roles := config.GetRoles(config.ContextUserIDKey)
+				roles, err := resolveRoles(config, email)
+				if err != nil {
+					return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+				}
 				if len(roles) == 0 {
 					return unauthorized(errors.New("no roles found"))
 				}
@@ -181,6 +307,9 @@ func WithConfig(config Config) echo.MiddlewareFunc {
 			// return next(c)
 			wantUser := c.Request().Header.Get("X-GetUser")
 			if wantUser == "true" {
+				if client == nil {
+					return unauthorized(errors.New("X-GetUser requires one of CredentialJSON, CredentialsFile or UseADC to be configured"))
+				}
 				user, err := client.GetUser(context.Background(), tok.UID)
 				if err != nil {
 					return unauthorized(err)
@@ -191,6 +320,18 @@ func WithConfig(config Config) echo.MiddlewareFunc {
 			return next(c)
 		}
 	}
+
+	if config.AppCheck == nil {
+		return authMiddleware, nil
+	}
+
+	appCheckMiddleware, err := NewAppCheck(*config.AppCheck)
+	if err != nil {
+		return nil, fmt.Errorf("firebaseauth: configuring AppCheck: %w", err)
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return appCheckMiddleware(authMiddleware(next))
+	}, nil
 }
 
 func unauthorized(err error) *echo.HTTPError {
@@ -0,0 +1,51 @@
+package firebaseauth
+
+import (
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestNewValidationErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{
+			name:   "no credentials and no KeySource",
+			config: Config{},
+		},
+		{
+			name:   "KeySource without ProjectID",
+			config: Config{KeySource: NewStaticKeySource(nil)},
+		},
+		{
+			name:   "AppCheck without ProjectNumber",
+			config: Config{KeySource: NewStaticKeySource(nil), ProjectID: "proj", AppCheck: &AppCheckConfig{}},
+		},
+		{
+			name:   "TenantResolver without credentials",
+			config: Config{TenantResolver: func(echo.Context) (string, error) { return "", nil }},
+		},
+		{
+			name:   "SessionCookieMode combined with KeySource",
+			config: Config{KeySource: NewStaticKeySource(nil), ProjectID: "proj", SessionCookieMode: true},
+		},
+		{
+			name:   "SessionCookieMode without credentials",
+			config: Config{SessionCookieMode: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mw, err := New(tt.config)
+			if err == nil {
+				t.Fatalf("New() = (%v, nil), want a non-nil error", mw)
+			}
+			if mw != nil {
+				t.Errorf("New() returned a non-nil middleware alongside the error")
+			}
+		})
+	}
+}
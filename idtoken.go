@@ -0,0 +1,61 @@
+package firebaseauth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	fbauth "firebase.google.com/go/v4/auth"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const googleIDTokenIssuerBase = "https://securetoken.google.com/"
+
+// verifyIDTokenLocal verifies the RS256 signature, issuer, audience and
+// standard time claims of a Firebase ID token against keySource, without
+// making a network call to the Firebase Admin API.
+func verifyIDTokenLocal(raw string, keySource KeySource, projectID string) (*fbauth.Token, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (interface{}, error) {
+		if t.Method.Alg() != "RS256" {
+			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+		}
+		kid, _ := t.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.New("token is missing kid header")
+		}
+		return keySource.Key(kid)
+	}, jwt.WithValidMethods([]string{"RS256"}))
+	if err != nil {
+		return nil, err
+	}
+
+	wantIssuer := googleIDTokenIssuerBase + projectID
+	if iss, _ := claims.GetIssuer(); iss != wantIssuer {
+		return nil, fmt.Errorf("unexpected issuer: %q", iss)
+	}
+	if aud, _ := claims.GetAudience(); !containsAny(aud, projectID) {
+		return nil, fmt.Errorf("unexpected audience: %v", aud)
+	}
+	sub, _ := claims.GetSubject()
+	if sub == "" {
+		return nil, errors.New("token is missing sub claim")
+	}
+	if authTime, ok := claims["auth_time"].(float64); ok && int64(authTime) > time.Now().Unix() {
+		return nil, errors.New("auth_time is in the future")
+	}
+
+	b, err := json.Marshal(map[string]interface{}(claims))
+	if err != nil {
+		return nil, err
+	}
+	tok := &fbauth.Token{}
+	if err := json.Unmarshal(b, tok); err != nil {
+		return nil, err
+	}
+	tok.UID = sub
+	tok.Claims = claims
+
+	return tok, nil
+}
@@ -0,0 +1,98 @@
+package firebaseauth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testKid = "test-kid"
+
+func signTestToken(t *testing.T, priv *rsa.PrivateKey, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = testKid
+
+	raw, err := token.SignedString(priv)
+	if err != nil {
+		t.Fatalf("signing test token: %v", err)
+	}
+	return raw
+}
+
+func TestVerifyIDTokenLocal(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating test key: %v", err)
+	}
+	keySource := NewStaticKeySource(map[string]*rsa.PublicKey{testKid: &priv.PublicKey})
+
+	const projectID = "my-project"
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":       "https://securetoken.google.com/" + projectID,
+			"aud":       projectID,
+			"sub":       "user-123",
+			"auth_time": float64(time.Now().Add(-time.Minute).Unix()),
+		}
+	}
+
+	t.Run("valid token", func(t *testing.T) {
+		raw := signTestToken(t, priv, validClaims())
+		tok, err := verifyIDTokenLocal(raw, keySource, projectID)
+		if err != nil {
+			t.Fatalf("verifyIDTokenLocal() error = %v, want nil", err)
+		}
+		if tok.UID != "user-123" {
+			t.Errorf("UID = %q, want %q", tok.UID, "user-123")
+		}
+	})
+
+	t.Run("wrong issuer", func(t *testing.T) {
+		claims := validClaims()
+		claims["iss"] = "https://securetoken.google.com/other-project"
+		raw := signTestToken(t, priv, claims)
+		if _, err := verifyIDTokenLocal(raw, keySource, projectID); err == nil {
+			t.Error("verifyIDTokenLocal() error = nil, want an issuer mismatch error")
+		}
+	})
+
+	t.Run("wrong audience", func(t *testing.T) {
+		claims := validClaims()
+		claims["aud"] = "other-project"
+		raw := signTestToken(t, priv, claims)
+		if _, err := verifyIDTokenLocal(raw, keySource, projectID); err == nil {
+			t.Error("verifyIDTokenLocal() error = nil, want an audience mismatch error")
+		}
+	})
+
+	t.Run("missing subject", func(t *testing.T) {
+		claims := validClaims()
+		delete(claims, "sub")
+		raw := signTestToken(t, priv, claims)
+		if _, err := verifyIDTokenLocal(raw, keySource, projectID); err == nil {
+			t.Error("verifyIDTokenLocal() error = nil, want a missing sub error")
+		}
+	})
+
+	t.Run("auth_time in the future", func(t *testing.T) {
+		claims := validClaims()
+		claims["auth_time"] = float64(time.Now().Add(time.Hour).Unix())
+		raw := signTestToken(t, priv, claims)
+		if _, err := verifyIDTokenLocal(raw, keySource, projectID); err == nil {
+			t.Error("verifyIDTokenLocal() error = nil, want a future auth_time error")
+		}
+	})
+
+	t.Run("unknown kid", func(t *testing.T) {
+		raw := signTestToken(t, priv, validClaims())
+		emptySource := NewStaticKeySource(nil)
+		if _, err := verifyIDTokenLocal(raw, emptySource, projectID); err == nil {
+			t.Error("verifyIDTokenLocal() error = nil, want an unknown kid error")
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package firebaseauth
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+/**
+Google Firebase AUTH - role-based authorization helpers
+*/
+
+// GetRolesFunc is an external closure function that resolves a user's roles
+// by email. A non-nil error is treated as a backend failure and surfaces as
+// a "500 - Internal Server Error"; a nil error with zero roles is treated as
+// "no roles found" and surfaces as a "401 - Unauthorized".
+type GetRolesFunc func(email string) (roles []string, err error)
+
+// ErrInsufficientRole is returned by RequireRoles and RequireAnyRole when
+// the authenticated user's roles don't satisfy the requirement.
+var ErrInsufficientRole = echo.NewHTTPError(http.StatusForbidden, "Insufficient role")
+
+type roleCacheEntry struct {
+	roles     []string
+	expiresAt time.Time
+}
+
+// RoleCache caches GetRoles results by email for TTL, so that GetRoles isn't
+// invoked on every request. See `Config.RoleCache`.
+type RoleCache struct {
+	TTL time.Duration
+
+	mu      sync.Mutex
+	entries map[string]roleCacheEntry
+}
+
+// NewRoleCache returns a RoleCache that caches each email's roles for ttl.
+func NewRoleCache(ttl time.Duration) *RoleCache {
+	return &RoleCache{TTL: ttl}
+}
+
+func (rc *RoleCache) get(email string) ([]string, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	entry, ok := rc.entries[email]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.roles, true
+}
+
+func (rc *RoleCache) set(email string, roles []string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if rc.entries == nil {
+		rc.entries = make(map[string]roleCacheEntry)
+	}
+	rc.entries[email] = roleCacheEntry{roles: roles, expiresAt: time.Now().Add(rc.TTL)}
+}
+
+// resolveRoles returns email's roles, consulting config.RoleCache first and
+// populating it with the result of config.GetRoles on a miss.
+func resolveRoles(config Config, email string) ([]string, error) {
+	if config.RoleCache != nil {
+		if roles, ok := config.RoleCache.get(email); ok {
+			return roles, nil
+		}
+	}
+
+	roles, err := config.GetRoles(email)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.RoleCache != nil {
+		config.RoleCache.set(email, roles)
+	}
+	return roles, nil
+}
+
+// RequireRoles returns a middleware that lets the request through only if
+// ContextKeyRoles in context holds every one of roles. It must run after a
+// FirebaseAuth middleware configured with GetRoles, which populates
+// ContextKeyRoles. Requests lacking any required role get a
+// "403 - Forbidden" error.
+func RequireRoles(roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			granted, _ := c.Get(ContextKeyRoles).([]string)
+			for _, role := range roles {
+				if !containsRole(granted, role) {
+					return ErrInsufficientRole
+				}
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireAnyRole returns a middleware that lets the request through if
+// ContextKeyRoles in context holds at least one of roles. It must run after
+// a FirebaseAuth middleware configured with GetRoles, which populates
+// ContextKeyRoles. Requests matching none of the roles get a
+// "403 - Forbidden" error.
+func RequireAnyRole(roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			granted, _ := c.Get(ContextKeyRoles).([]string)
+			for _, role := range roles {
+				if containsRole(granted, role) {
+					return next(c)
+				}
+			}
+			return ErrInsufficientRole
+		}
+	}
+}
+
+func containsRole(granted []string, role string) bool {
+	for _, g := range granted {
+		if g == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package firebaseauth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestRoleCache(t *testing.T) {
+	rc := NewRoleCache(10 * time.Millisecond)
+
+	rc.set("user@example.com", []string{"admin"})
+
+	roles, ok := rc.get("user@example.com")
+	if !ok {
+		t.Fatal("get() ok = false right after set(), want true")
+	}
+	if len(roles) != 1 || roles[0] != "admin" {
+		t.Errorf("get() roles = %v, want [admin]", roles)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if _, ok := rc.get("user@example.com"); ok {
+		t.Error("get() ok = true after TTL expiry, want false")
+	}
+}
+
+func newRoleContext(granted []string) echo.Context {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if granted != nil {
+		c.Set(ContextKeyRoles, granted)
+	}
+	return c
+}
+
+func TestRequireRoles(t *testing.T) {
+	ok := func(echo.Context) error { return nil }
+
+	tests := []struct {
+		name    string
+		granted []string
+		require []string
+		wantErr bool
+	}{
+		{"has all required roles", []string{"admin", "editor"}, []string{"admin", "editor"}, false},
+		{"missing one required role", []string{"admin"}, []string{"admin", "editor"}, true},
+		{"no roles in context", nil, []string{"admin"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newRoleContext(tt.granted)
+			err := RequireRoles(tt.require...)(ok)(c)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RequireRoles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRequireAnyRole(t *testing.T) {
+	ok := func(echo.Context) error { return nil }
+
+	tests := []struct {
+		name    string
+		granted []string
+		require []string
+		wantErr bool
+	}{
+		{"has one of the roles", []string{"editor"}, []string{"admin", "editor"}, false},
+		{"has none of the roles", []string{"viewer"}, []string{"admin", "editor"}, true},
+		{"empty role requirement denies", []string{"admin"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newRoleContext(tt.granted)
+			err := RequireAnyRole(tt.require...)(ok)(c)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RequireAnyRole() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
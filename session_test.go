@@ -0,0 +1,95 @@
+package firebaseauth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestNewSessionLoginHandlerBadRequest(t *testing.T) {
+	handler := NewSessionLoginHandler(nil, SessionCookieConfig{})
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"missing idToken", `{}`},
+		{"empty idToken", `{"idToken":""}`},
+		{"malformed JSON", `not json`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/sessionLogin", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler(c)
+			httpErr, ok := err.(*echo.HTTPError)
+			if !ok {
+				t.Fatalf("handler() error = %v (%T), want *echo.HTTPError", err, err)
+			}
+			if httpErr.Code != http.StatusBadRequest {
+				t.Errorf("handler() error code = %d, want %d", httpErr.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestSessionCookieConfigWithDefaults(t *testing.T) {
+	config := SessionCookieConfig{}.withDefaults()
+	if config.Name != DefaultSessionCookieConfig.Name {
+		t.Errorf("Name = %q, want %q", config.Name, DefaultSessionCookieConfig.Name)
+	}
+	if config.ExpiresIn != DefaultSessionCookieConfig.ExpiresIn {
+		t.Errorf("ExpiresIn = %v, want %v", config.ExpiresIn, DefaultSessionCookieConfig.ExpiresIn)
+	}
+
+	custom := SessionCookieConfig{Name: "custom", ExpiresIn: time.Hour}.withDefaults()
+	if custom.Name != "custom" {
+		t.Errorf("Name = %q, want %q", custom.Name, "custom")
+	}
+	if custom.ExpiresIn != time.Hour {
+		t.Errorf("ExpiresIn = %v, want %v", custom.ExpiresIn, time.Hour)
+	}
+}
+
+func TestSessionCookie(t *testing.T) {
+	config := SessionCookieConfig{
+		Name:      "__session",
+		ExpiresIn: 2 * time.Hour,
+		SameSite:  http.SameSiteStrictMode,
+		Secure:    true,
+		Domain:    "example.com",
+	}
+
+	cookie := sessionCookie(config, "cookie-value")
+
+	if cookie.Name != "__session" {
+		t.Errorf("Name = %q, want %q", cookie.Name, "__session")
+	}
+	if cookie.Value != "cookie-value" {
+		t.Errorf("Value = %q, want %q", cookie.Value, "cookie-value")
+	}
+	if cookie.MaxAge != int((2 * time.Hour).Seconds()) {
+		t.Errorf("MaxAge = %d, want %d", cookie.MaxAge, int((2*time.Hour).Seconds()))
+	}
+	if cookie.SameSite != http.SameSiteStrictMode {
+		t.Errorf("SameSite = %v, want %v", cookie.SameSite, http.SameSiteStrictMode)
+	}
+	if !cookie.Secure {
+		t.Error("Secure = false, want true")
+	}
+	if cookie.Domain != "example.com" {
+		t.Errorf("Domain = %q, want %q", cookie.Domain, "example.com")
+	}
+	if !cookie.HttpOnly {
+		t.Error("HttpOnly = false, want true")
+	}
+}
@@ -0,0 +1,55 @@
+package firebaseauth
+
+import (
+	"fmt"
+	"sync"
+
+	fbauth "firebase.google.com/go/v4/auth"
+	"github.com/labstack/echo/v4"
+)
+
+/**
+Google Firebase AUTH - multi-tenant support
+*/
+
+// TenantResolver resolves the Firebase Auth tenant a request belongs to,
+// e.g. from a subdomain, a header, or the request's own (unverified)
+// `firebase.tenant` JWT claim. See `Config.TenantResolver`.
+type TenantResolver func(c echo.Context) (tenantID string, err error)
+
+// tenantClientCache lazily creates and caches a *fbauth.TenantClient per
+// tenant ID, so AuthForTenant isn't called on every request.
+type tenantClientCache struct {
+	tm      *fbauth.TenantManager
+	clients sync.Map // tenantID string -> *fbauth.TenantClient
+}
+
+func newTenantClientCache(tm *fbauth.TenantManager) *tenantClientCache {
+	return &tenantClientCache{tm: tm}
+}
+
+func (c *tenantClientCache) client(tenantID string) (*fbauth.TenantClient, error) {
+	if v, ok := c.clients.Load(tenantID); ok {
+		return v.(*fbauth.TenantClient), nil
+	}
+
+	client, err := c.tm.AuthForTenant(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("firebaseauth: getting Auth client for tenant %q: %w", tenantID, err)
+	}
+
+	actual, _ := c.clients.LoadOrStore(tenantID, client)
+	return actual.(*fbauth.TenantClient), nil
+}
+
+func tenantAllowed(allowedTenants []string, tenantID string) bool {
+	if len(allowedTenants) == 0 {
+		return true
+	}
+	for _, t := range allowedTenants {
+		if t == tenantID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,24 @@
+package firebaseauth
+
+import "testing"
+
+func TestTenantAllowed(t *testing.T) {
+	tests := []struct {
+		name           string
+		allowedTenants []string
+		tenantID       string
+		want           bool
+	}{
+		{"empty allowlist allows everything", nil, "tenant-a", true},
+		{"allowed tenant", []string{"tenant-a", "tenant-b"}, "tenant-a", true},
+		{"disallowed tenant", []string{"tenant-a", "tenant-b"}, "tenant-c", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tenantAllowed(tt.allowedTenants, tt.tenantID); got != tt.want {
+				t.Errorf("tenantAllowed(%v, %q) = %v, want %v", tt.allowedTenants, tt.tenantID, got, tt.want)
+			}
+		})
+	}
+}